@@ -0,0 +1,54 @@
+package api
+
+import (
+	"fmt"
+
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+)
+
+// IndexerProgress reports how far a background section indexer has caught
+// up, in terms of both sections committed and the EVM height that implies.
+type IndexerProgress struct {
+	Sections    uint64 `json:"sections"`
+	SectionSize uint64 `json:"sectionSize"`
+	Height      uint64 `json:"height"`
+}
+
+// SectionProgress is implemented by any background indexer that builds a
+// fixed-size-section index and can report how far it's gotten, e.g.
+// *storage.ChainIndexer and *pebble.BloombitsIndexer.
+type SectionProgress interface {
+	Progress() (sections uint64, size uint64, err error)
+}
+
+// AdminIndexerAPI exposes progress endpoints for the gateway's background
+// section indexers (e.g. the bloombits indexer) under the `admin` JSON-RPC
+// namespace, so operators can tell whether an indexer has caught up to the
+// chain tip.
+type AdminIndexerAPI struct {
+	indexers map[string]SectionProgress
+}
+
+// NewAdminIndexerAPI registers the named indexers this API can report on.
+func NewAdminIndexerAPI(indexers map[string]SectionProgress) *AdminIndexerAPI {
+	return &AdminIndexerAPI{indexers: indexers}
+}
+
+// IndexerProgress returns the indexing progress for the named indexer.
+func (a *AdminIndexerAPI) IndexerProgress(name string) (*IndexerProgress, error) {
+	indexer, ok := a.indexers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown indexer: %s", errs.ErrInvalid, name)
+	}
+
+	sections, size, err := indexer.Progress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read progress for indexer %s: %w", name, err)
+	}
+
+	return &IndexerProgress{
+		Sections:    sections,
+		SectionSize: size,
+		Height:      sections * size,
+	}, nil
+}
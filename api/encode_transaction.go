@@ -16,7 +16,11 @@ const blockGasLimit uint64 = 15_000_000
 // `EVM.dryRun` inside Cadence scripts, meaning that no state change
 // will occur.
 // This is only useful for `eth_estimateGas` and `eth_call` endpoints.
-func encodeTxFromArgs(args TransactionArgs) ([]byte, error) {
+//
+// chainID is only used to populate the ChainID field of EIP-1559/2930
+// transactions; dryRun ignores it for signature purposes since the
+// transaction is never signed.
+func encodeTxFromArgs(args TransactionArgs, chainID *big.Int) ([]byte, error) {
 	var data []byte
 	if args.Data != nil {
 		data = *args.Data
@@ -36,16 +40,78 @@ func encodeTxFromArgs(args TransactionArgs) ([]byte, error) {
 		value = args.Value.ToInt()
 	}
 
-	tx := types.NewTx(
-		&types.LegacyTx{
-			Nonce:    0,
-			To:       args.To,
-			Value:    value,
-			Gas:      gasLimit,
-			GasPrice: big.NewInt(0),
-			Data:     data,
-		},
-	)
+	if args.GasPrice != nil && (args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil) {
+		return nil, fmt.Errorf(
+			"%w: both gasPrice and maxFeePerGas/maxPriorityFeePerGas specified",
+			errs.ErrInvalid,
+		)
+	}
+
+	var accessList types.AccessList
+	if args.AccessList != nil {
+		accessList = *args.AccessList
+	}
+
+	var tx *types.Transaction
+	switch {
+	case args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil:
+		gasFeeCap := big.NewInt(0)
+		if args.MaxFeePerGas != nil {
+			gasFeeCap = args.MaxFeePerGas.ToInt()
+		}
+		gasTipCap := big.NewInt(0)
+		if args.MaxPriorityFeePerGas != nil {
+			gasTipCap = args.MaxPriorityFeePerGas.ToInt()
+		}
+
+		tx = types.NewTx(
+			&types.DynamicFeeTx{
+				ChainID:    chainID,
+				Nonce:      0,
+				To:         args.To,
+				Value:      value,
+				Gas:        gasLimit,
+				GasFeeCap:  gasFeeCap,
+				GasTipCap:  gasTipCap,
+				Data:       data,
+				AccessList: accessList,
+			},
+		)
+	case args.AccessList != nil:
+		gasPrice := big.NewInt(0)
+		if args.GasPrice != nil {
+			gasPrice = args.GasPrice.ToInt()
+		}
+
+		tx = types.NewTx(
+			&types.AccessListTx{
+				ChainID:    chainID,
+				Nonce:      0,
+				To:         args.To,
+				Value:      value,
+				Gas:        gasLimit,
+				GasPrice:   gasPrice,
+				Data:       data,
+				AccessList: accessList,
+			},
+		)
+	default:
+		gasPrice := big.NewInt(0)
+		if args.GasPrice != nil {
+			gasPrice = args.GasPrice.ToInt()
+		}
+
+		tx = types.NewTx(
+			&types.LegacyTx{
+				Nonce:    0,
+				To:       args.To,
+				Value:    value,
+				Gas:      gasLimit,
+				GasPrice: gasPrice,
+				Data:     data,
+			},
+		)
+	}
 
 	enc, err := tx.MarshalBinary()
 	if err != nil {
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/onflow/go-ethereum/common"
+	"github.com/onflow/go-ethereum/common/hexutil"
+	"github.com/onflow/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	errs "github.com/onflow/flow-evm-gateway/models/errors"
+)
+
+func bigToHex(v int64) *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(v))
+}
+
+func TestEncodeTxFromArgs(t *testing.T) {
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	chainID := big.NewInt(646)
+
+	t.Run("legacy when no fee market fields are set", func(t *testing.T) {
+		args := TransactionArgs{To: &to, Value: bigToHex(1)}
+
+		enc, err := encodeTxFromArgs(args, chainID)
+		require.NoError(t, err)
+
+		var tx types.Transaction
+		require.NoError(t, tx.UnmarshalBinary(enc))
+		require.Equal(t, uint8(types.LegacyTxType), tx.Type())
+	})
+
+	t.Run("access list tx when only an access list is set", func(t *testing.T) {
+		args := TransactionArgs{
+			To:         &to,
+			Value:      bigToHex(1),
+			AccessList: &types.AccessList{{Address: to}},
+		}
+
+		enc, err := encodeTxFromArgs(args, chainID)
+		require.NoError(t, err)
+
+		var tx types.Transaction
+		require.NoError(t, tx.UnmarshalBinary(enc))
+		require.Equal(t, uint8(types.AccessListTxType), tx.Type())
+		require.Equal(t, chainID, tx.ChainId())
+	})
+
+	t.Run("dynamic fee tx when 1559 fields are set", func(t *testing.T) {
+		args := TransactionArgs{
+			To:                   &to,
+			Value:                bigToHex(1),
+			MaxFeePerGas:         bigToHex(100),
+			MaxPriorityFeePerGas: bigToHex(2),
+			AccessList:           &types.AccessList{{Address: to}},
+		}
+
+		enc, err := encodeTxFromArgs(args, chainID)
+		require.NoError(t, err)
+
+		var tx types.Transaction
+		require.NoError(t, tx.UnmarshalBinary(enc))
+		require.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+		require.Equal(t, chainID, tx.ChainId())
+		require.Equal(t, big.NewInt(100), tx.GasFeeCap())
+		require.Equal(t, big.NewInt(2), tx.GasTipCap())
+	})
+
+	t.Run("dynamic fee tx when only maxPriorityFeePerGas is set", func(t *testing.T) {
+		args := TransactionArgs{
+			To:                   &to,
+			Value:                bigToHex(1),
+			MaxPriorityFeePerGas: bigToHex(2),
+		}
+
+		enc, err := encodeTxFromArgs(args, chainID)
+		require.NoError(t, err)
+
+		var tx types.Transaction
+		require.NoError(t, tx.UnmarshalBinary(enc))
+		require.Equal(t, uint8(types.DynamicFeeTxType), tx.Type())
+	})
+
+	t.Run("rejects gasPrice combined with maxFeePerGas", func(t *testing.T) {
+		args := TransactionArgs{
+			To:           &to,
+			Value:        bigToHex(1),
+			GasPrice:     bigToHex(1),
+			MaxFeePerGas: bigToHex(100),
+		}
+
+		_, err := encodeTxFromArgs(args, chainID)
+		require.ErrorIs(t, err, errs.ErrInvalid)
+	})
+
+	t.Run("rejects gasPrice combined with maxPriorityFeePerGas", func(t *testing.T) {
+		args := TransactionArgs{
+			To:                   &to,
+			Value:                bigToHex(1),
+			GasPrice:             bigToHex(1),
+			MaxPriorityFeePerGas: bigToHex(2),
+		}
+
+		_, err := encodeTxFromArgs(args, chainID)
+		require.ErrorIs(t, err, errs.ErrInvalid)
+	})
+}
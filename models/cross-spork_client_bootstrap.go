@@ -0,0 +1,382 @@
+package models
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+// bootstrapWorkersPerSpork is how many heights a single spork host will
+// serve concurrently during a BootstrapRange call.
+const bootstrapWorkersPerSpork = 8
+
+// bootstrapRatePerSecond caps the number of requests per second issued to
+// a single spork host; historical/archive access nodes serving old sporks
+// are often rate-limited more aggressively than the current spork.
+const bootstrapRatePerSecond = 40.0
+
+// bootstrapMaxRetries is how many attempts, the first one included, are
+// made for a single height (with exponential backoff between them) before
+// BootstrapRange gives up on the whole range.
+const bootstrapMaxRetries = 5
+
+// BlockBatch is a contiguous, gap-free run of block headers in height
+// order, ready to hand to the ingestion pipeline.
+type BlockBatch struct {
+	Start  uint64
+	Blocks []*flow.BlockHeader
+}
+
+// BootstrapProgress is a point-in-time snapshot of a running
+// BootstrapRange call, meant for metrics/status reporting.
+type BootstrapProgress struct {
+	Start     uint64
+	End       uint64
+	Requested uint64 // heights fetched from a spork host so far
+	Delivered uint64 // heights handed to the caller via contiguous batches
+}
+
+// Bootstrap is the handle returned by BootstrapRange: it lets the caller
+// poll progress and wait for completion while results stream out on the
+// channel passed to BootstrapRange.
+type Bootstrap struct {
+	start, end uint64
+
+	requested atomic64
+	delivered atomic64
+
+	done chan struct{}
+	err  error
+}
+
+// Progress returns a snapshot of how far the bootstrap has gotten.
+func (b *Bootstrap) Progress() BootstrapProgress {
+	return BootstrapProgress{
+		Start:     b.start,
+		End:       b.end,
+		Requested: b.requested.load(),
+		Delivered: b.delivered.load(),
+	}
+}
+
+// Wait blocks until the bootstrap finishes (successfully or not) and
+// returns the error it finished with, if any.
+func (b *Bootstrap) Wait() error {
+	<-b.done
+	return b.err
+}
+
+// atomic64 is a tiny mutex-guarded counter; introduced instead of
+// sync/atomic.Uint64 so this file has no minimum Go version beyond what the
+// rest of the package already requires.
+type atomic64 struct {
+	mux sync.Mutex
+	v   uint64
+}
+
+func (a *atomic64) add(n uint64) {
+	a.mux.Lock()
+	a.v += n
+	a.mux.Unlock()
+}
+
+func (a *atomic64) load() uint64 {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	return a.v
+}
+
+// BootstrapRange fetches block headers for every height in [start, end],
+// fanning out concurrent workers grouped by spork host so a fresh gateway
+// sync downloads history from multiple sporks in parallel rather than one
+// request at a time. Results are reassembled into contiguous, gap-free
+// batches and streamed to out in height order. out is closed when the
+// range has been fully delivered or the returned Bootstrap's Wait() returns
+// a non-nil error.
+func (c *CrossSporkClient) BootstrapRange(
+	ctx context.Context,
+	start, end uint64,
+	out chan<- BlockBatch,
+) *Bootstrap {
+	b := &Bootstrap{start: start, end: end, done: make(chan struct{})}
+
+	go func() {
+		defer close(out)
+		defer close(b.done)
+		b.err = c.runBootstrap(ctx, start, end, out, b)
+	}()
+
+	return b
+}
+
+// sporkLimiter bounds and paces requests to a single spork host: sem caps
+// the number of in-flight heights, bucket caps the request rate.
+type sporkLimiter struct {
+	sem    chan struct{}
+	bucket *tokenBucket
+}
+
+func (c *CrossSporkClient) runBootstrap(
+	ctx context.Context,
+	start, end uint64,
+	out chan<- BlockBatch,
+	progress *Bootstrap,
+) error {
+	if start > end {
+		return fmt.Errorf("start value %d is bigger than end value %d", start, end)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		height uint64
+		header *flow.BlockHeader
+		err    error
+	}
+
+	results := make(chan result, bootstrapWorkersPerSpork*4)
+
+	var limitersMux sync.Mutex
+	limiters := make(map[any]*sporkLimiter)
+
+	limiterFor := func(identity any) *sporkLimiter {
+		limitersMux.Lock()
+		defer limitersMux.Unlock()
+		l, ok := limiters[identity]
+		if !ok {
+			l = &sporkLimiter{
+				sem:    make(chan struct{}, bootstrapWorkersPerSpork),
+				bucket: newTokenBucket(bootstrapRatePerSecond),
+			}
+			limiters[identity] = l
+		}
+		return l
+	}
+
+	// The scheduling loop below must run concurrently with the
+	// `for r := range results` drain loop further down, not before it:
+	// results is bounded to bootstrapWorkersPerSpork*4, so once enough
+	// workers finish and block trying to send, every worker goroutine (and
+	// the semaphore slot it holds) would be stuck forever if nothing were
+	// draining yet. Running it in its own goroutine, with wg.Wait/close
+	// chained after the loop in the same goroutine, also keeps every
+	// wg.Add ordered before the matching wg.Wait.
+	var wg sync.WaitGroup
+	var produceErr error
+	go func() {
+	produce:
+		for height := start; height <= end; height++ {
+			height := height
+
+			var identity any = c.getClientForHeight(height)
+			limiter := limiterFor(identity)
+
+			select {
+			case limiter.sem <- struct{}{}:
+			case <-ctx.Done():
+				produceErr = ctx.Err()
+				break produce
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-limiter.sem }()
+
+				header, err := c.fetchWithRetry(ctx, limiter, height)
+				progress.requested.add(1)
+				results <- result{height: height, header: header, err: err}
+			}()
+		}
+
+		wg.Wait()
+		close(results)
+	}()
+
+	// drain discards every result still to come after we've decided to stop
+	// consuming early. Workers up to bootstrapWorkersPerSpork per active
+	// spork may already be blocked sending on results (it's only buffered
+	// bootstrapWorkersPerSpork*4 deep); cancel makes fetchWithRetry/the
+	// token bucket return promptly, but those goroutines still need
+	// somewhere to send before they can release their semaphore slot and
+	// exit. Without draining, they'd block forever and leak.
+	drain := func() {
+		for range results {
+		}
+	}
+
+	reorder := newReorderBuffer(start)
+	var tail *flow.BlockHeader
+
+	for r := range results {
+		if r.err != nil {
+			cancel()
+			drain()
+			return fmt.Errorf("failed to fetch block header at height %d: %w", r.height, r.err)
+		}
+
+		reorder.push(r.height, r.header)
+
+		for {
+			batchStart, headers, ok := reorder.popContiguous()
+			if !ok {
+				break
+			}
+
+			for _, h := range headers {
+				if tail != nil && h.ParentID != tail.ID {
+					cancel()
+					drain()
+					return fmt.Errorf(
+						"parent hash mismatch at height %d: expected parent %s, got %s",
+						h.Height,
+						tail.ID,
+						h.ParentID,
+					)
+				}
+				tail = h
+			}
+
+			select {
+			case out <- BlockBatch{Start: batchStart, Blocks: headers}:
+				progress.delivered.add(uint64(len(headers)))
+			case <-ctx.Done():
+				cancel()
+				drain()
+				return ctx.Err()
+			}
+		}
+	}
+
+	return produceErr
+}
+
+// fetchWithRetry fetches a single height, retrying with exponential
+// backoff on failure, and paced by the spork's token bucket.
+func (c *CrossSporkClient) fetchWithRetry(ctx context.Context, limiter *sporkLimiter, height uint64) (*flow.BlockHeader, error) {
+	var lastErr error
+
+	for attempt := 0; attempt < bootstrapMaxRetries; attempt++ {
+		if err := limiter.bucket.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		header, err := c.GetBlockHeaderByHeight(ctx, height)
+		if err == nil {
+			return header, nil
+		}
+		lastErr = err
+
+		backoff := time.Duration(1<<attempt) * 100 * time.Millisecond
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, fmt.Errorf("failed after %d attempts: %w", bootstrapMaxRetries, lastErr)
+}
+
+// reorderBuffer reassembles out-of-order fetch results into contiguous,
+// gap-free runs starting at `next`, using a min-heap so results can arrive
+// in any order without blocking faster workers.
+type reorderBuffer struct {
+	next    uint64
+	pending heightHeap
+}
+
+func newReorderBuffer(start uint64) *reorderBuffer {
+	return &reorderBuffer{next: start}
+}
+
+func (r *reorderBuffer) push(height uint64, header *flow.BlockHeader) {
+	heap.Push(&r.pending, &heightItem{height: height, header: header})
+}
+
+// popContiguous drains and returns every buffered item starting at `next`
+// with no gaps, advancing `next` past them. ok is false if the lowest
+// buffered item isn't `next` yet.
+func (r *reorderBuffer) popContiguous() (start uint64, headers []*flow.BlockHeader, ok bool) {
+	if r.pending.Len() == 0 || r.pending[0].height != r.next {
+		return 0, nil, false
+	}
+
+	start = r.next
+	for r.pending.Len() > 0 && r.pending[0].height == r.next {
+		item := heap.Pop(&r.pending).(*heightItem)
+		headers = append(headers, item.header)
+		r.next++
+	}
+
+	return start, headers, true
+}
+
+type heightItem struct {
+	height uint64
+	header *flow.BlockHeader
+}
+
+// heightHeap is a container/heap.Interface min-heap ordered by height.
+type heightHeap []*heightItem
+
+func (h heightHeap) Len() int            { return len(h) }
+func (h heightHeap) Less(i, j int) bool  { return h[i].height < h[j].height }
+func (h heightHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *heightHeap) Push(x interface{}) { *h = append(*h, x.(*heightItem)) }
+func (h *heightHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// tokenBucket is a minimal token-bucket rate limiter: up to `rate` tokens
+// are available per second, refilled continuously rather than in discrete
+// ticks, capped at `rate` tokens of burst.
+type tokenBucket struct {
+	mux    sync.Mutex
+	tokens float64
+	rate   float64
+	last   time.Time
+}
+
+func newTokenBucket(ratePerSecond float64) *tokenBucket {
+	return &tokenBucket{
+		tokens: ratePerSecond,
+		rate:   ratePerSecond,
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx is cancelled.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mux.Lock()
+		now := time.Now()
+		b.tokens = math.Min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mux.Unlock()
+			return nil
+		}
+
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mux.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestCrossSporkClient_MultiClient(t *testing.T) {
@@ -50,4 +51,33 @@ func TestCrossSporkClient_ExistingHeight(t *testing.T) {
 
 	err = client.AddSpork(100, "host3.com")
 	require.EqualError(t, err, "provided last height already exists")
+}
+
+// TestCrossSporkClient_BootstrapRange_DrainsResultsOnError exercises
+// runBootstrap's error path: every fetch against an unreachable host fails,
+// so the first completed result should make runBootstrap cancel and return
+// without leaving any of the other in-flight worker goroutines blocked
+// forever trying to send on results. If they were left blocked, out would
+// never be closed and this test would time out.
+func TestCrossSporkClient_BootstrapRange_DrainsResultsOnError(t *testing.T) {
+	client, err := NewCrossSporkClient("invalid-host-that-does-not-exist.test", zerolog.Nop())
+	require.NoError(t, err)
+
+	out := make(chan BlockBatch)
+	b := client.BootstrapRange(context.Background(), 1, 200, out)
+
+	drained := make(chan struct{})
+	go func() {
+		for range out {
+		}
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(15 * time.Second):
+		t.Fatal("BootstrapRange never closed out after a fetch error; results were likely not drained")
+	}
+
+	require.Error(t, b.Wait())
 }
\ No newline at end of file
@@ -0,0 +1,344 @@
+package logs
+
+import (
+	"fmt"
+
+	"github.com/onflow/go-ethereum/common"
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+
+	"github.com/onflow/flow-evm-gateway/models"
+)
+
+// BitmapSource is the read side of the bloom-bits index a Matcher compiles
+// filter queries against. storage/pebble.BloombitsIndexer satisfies this.
+type BitmapSource interface {
+	// Sections reports how many sections have been fully indexed, and the
+	// number of blocks each section covers.
+	Sections() (sections uint64, size uint64, err error)
+	// Bitmap returns the compressed-then-decompressed bitmap for a single
+	// bloom bit position within a section.
+	Bitmap(section uint64, bit uint16) ([]byte, error)
+}
+
+// Matcher compiles `eth_getLogs`-style filter criteria (addresses and topic
+// slots) into the set of bloom-bits indices that must all be present for a
+// block to possibly contain a matching log, and evaluates that set against
+// the index section by section.
+//
+// Matching follows the same two-level OR/AND shape as go-ethereum's
+// `filters.Filter`: within one address/topic slot any of the supplied values
+// may match (OR), but every supplied slot must have a match (AND). Addresses
+// and each topic slot are themselves treated as a slot.
+type Matcher struct {
+	source BitmapSource
+
+	// filters[i] is the list of 3-bit-index triples (one per possible value
+	// in slot i) that satisfy that slot.
+	filters [][][3]uint
+}
+
+// NewMatcher compiles addresses and topics into bloom-bits indices. An empty
+// topics slot (no values) matches everything, same as an absent filter
+// criterion in `eth_getLogs`.
+func NewMatcher(source BitmapSource, addresses []common.Address, topics [][]common.Hash) *Matcher {
+	m := &Matcher{source: source}
+
+	if len(addresses) > 0 {
+		slot := make([][3]uint, len(addresses))
+		for i, addr := range addresses {
+			slot[i] = bloomIndexes(addr.Bytes())
+		}
+		m.filters = append(m.filters, slot)
+	}
+
+	for _, topicSlot := range topics {
+		if len(topicSlot) == 0 {
+			continue
+		}
+		slot := make([][3]uint, len(topicSlot))
+		for i, topic := range topicSlot {
+			slot[i] = bloomIndexes(topic.Bytes())
+		}
+		m.filters = append(m.filters, slot)
+	}
+
+	return m
+}
+
+// Candidates returns the block heights within [start, end] that might
+// contain a matching log, based only on the bloom-bits index. Callers must
+// still load the actual receipts/logs for each candidate and re-check them,
+// since bloom filters can false-positive.
+//
+// If the index hasn't caught up to `start` yet, ok is false. If it covers
+// `start` but not `end`, ok is true and the candidates stop at the last
+// indexed height; heights past it are not returned and are left for a
+// linear bloom scan, as MatchHeights does for the un-indexed tail.
+func (m *Matcher) Candidates(start, end uint64) (candidates []uint64, ok bool, err error) {
+	if start > end {
+		return nil, false, fmt.Errorf("start value %d is bigger than end value %d", start, end)
+	}
+
+	sections, size, err := m.source.Sections()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read bloom-bits progress: %w", err)
+	}
+
+	indexedUpTo := sections * size
+	if indexedUpTo <= start {
+		return nil, false, nil
+	}
+	if end >= indexedUpTo {
+		end = indexedUpTo - 1
+	}
+
+	firstSection := start / size
+	lastSection := end / size
+
+	for section := firstSection; section <= lastSection; section++ {
+		sectionStart := section * size
+		matched, err := m.matchSection(section, size)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, offset := range matched {
+			height := sectionStart + uint64(offset)
+			if height < start || height > end {
+				continue
+			}
+			candidates = append(candidates, height)
+		}
+	}
+
+	return candidates, true, nil
+}
+
+// matchSection ANDs together, for every filter slot, the OR of that slot's
+// value bitmaps, and returns the set-bit offsets (block positions within the
+// section) that satisfy every slot.
+func (m *Matcher) matchSection(section, size uint64) ([]uint64, error) {
+	if len(m.filters) == 0 {
+		// no criteria at all: every block in the section is a candidate.
+		all := make([]uint64, size)
+		for i := range all {
+			all[i] = uint64(i)
+		}
+		return all, nil
+	}
+
+	var result []byte
+	for _, slot := range m.filters {
+		slotBitmap, err := m.orSlot(section, slot)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = slotBitmap
+			continue
+		}
+		for i := range result {
+			result[i] &= slotBitmap[i]
+		}
+	}
+
+	return bitmapOffsets(result), nil
+}
+
+// orSlot fetches the bitmap for each of a slot's 3 bloom-bit indices per
+// value and ANDs them together (a value needs all 3 bits set), then ORs
+// across values (any value in the slot may match).
+func (m *Matcher) orSlot(section uint64, slot [][3]uint) ([]byte, error) {
+	var slotBitmap []byte
+
+	for _, idxs := range slot {
+		var valueBitmap []byte
+		for _, idx := range idxs {
+			bm, err := m.source.Bitmap(section, uint16(idx))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get bitmap for section %d bit %d: %w", section, idx, err)
+			}
+			if valueBitmap == nil {
+				valueBitmap = append([]byte(nil), bm...)
+				continue
+			}
+			for i := range valueBitmap {
+				valueBitmap[i] &= bm[i]
+			}
+		}
+
+		if slotBitmap == nil {
+			slotBitmap = valueBitmap
+			continue
+		}
+		for i := range slotBitmap {
+			slotBitmap[i] |= valueBitmap[i]
+		}
+	}
+
+	return slotBitmap, nil
+}
+
+// bloomIndexes returns the (up to) 3 bit positions that gethTypes.Bloom.Add
+// would set for data, by running Add and reading back which bits it flipped
+// rather than reimplementing go-ethereum's bloom9 hashing ourselves.
+func bloomIndexes(data []byte) [3]uint {
+	var b gethTypes.Bloom
+	b.Add(data)
+
+	var idxs [3]uint
+	found := 0
+	for i := 0; i < gethTypes.BloomBitLength && found < 3; i++ {
+		byteIdx := gethTypes.BloomByteLength - 1 - i/8
+		bitIdx := uint(i % 8)
+		if b[byteIdx]&(1<<bitIdx) != 0 {
+			idxs[found] = uint(i)
+			found++
+		}
+	}
+
+	// if Add's hashes collided on fewer than 3 distinct bits, repeat the last
+	// one so every entry is a real position of data's bloom.
+	for i := found; i > 0 && i < len(idxs); i++ {
+		idxs[i] = idxs[found-1]
+	}
+
+	return idxs
+}
+
+// BlockRangeBlooms is the per-height bloom read side Matcher falls back to
+// for the tail of a range the bloom-bits index hasn't caught up to yet.
+// storage/pebble.Receipts satisfies this via its existing
+// BloomsForBlockRange.
+type BlockRangeBlooms interface {
+	BloomsForBlockRange(start, end uint64) ([]*models.BloomsHeight, error)
+}
+
+// MatchHeights returns the block heights in [start, end] that might contain
+// a matching log, the same result eth_getLogs needs to narrow down before
+// loading and re-checking actual receipts. Heights the bloom-bits index has
+// already caught up to are resolved straight from the index via Candidates;
+// any tail beyond that falls back to fetching blooms the slow way, through
+// source, and bloom-testing them one block at a time exactly as eth_getLogs
+// did before the index existed.
+func (m *Matcher) MatchHeights(source BlockRangeBlooms, start, end uint64) ([]uint64, error) {
+	if start > end {
+		return nil, fmt.Errorf("start value %d is bigger than end value %d", start, end)
+	}
+
+	sections, size, err := m.source.Sections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bloom-bits progress: %w", err)
+	}
+	indexedUpTo := sections * size
+
+	var heights []uint64
+
+	if indexedUpTo > start {
+		indexedEnd := end
+		if indexedEnd >= indexedUpTo {
+			indexedEnd = indexedUpTo - 1
+		}
+
+		candidates, ok, err := m.Candidates(start, indexedEnd)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			heights = append(heights, candidates...)
+		}
+	}
+
+	if end >= indexedUpTo {
+		tailStart := start
+		if tailStart < indexedUpTo {
+			tailStart = indexedUpTo
+		}
+
+		tail, err := m.scanBlooms(source, tailStart, end)
+		if err != nil {
+			return nil, err
+		}
+		heights = append(heights, tail...)
+	}
+
+	return heights, nil
+}
+
+// scanBlooms is the linear per-block bloom test eth_getLogs relied on
+// before the bloom-bits index existed, kept as the fallback for ranges (or
+// parts of ranges) the index hasn't indexed yet.
+func (m *Matcher) scanBlooms(source BlockRangeBlooms, start, end uint64) ([]uint64, error) {
+	if start > end {
+		return nil, nil
+	}
+
+	blooms, err := source.BloomsForBlockRange(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blooms for range [%d, %d]: %w", start, end, err)
+	}
+
+	var heights []uint64
+	for _, bh := range blooms {
+		if m.matchesAny(bh.Blooms) {
+			heights = append(heights, bh.Height)
+		}
+	}
+	return heights, nil
+}
+
+// matchesAny reports whether any of a block's (possibly several, one per
+// transaction) blooms satisfies every filter slot, the same semantics the
+// rotated index applies by OR-ing a block's receipt blooms together before
+// indexing it.
+func (m *Matcher) matchesAny(blooms []*gethTypes.Bloom) bool {
+	for _, bloom := range blooms {
+		if m.matchesBloom(bloom) {
+			return true
+		}
+	}
+	return len(m.filters) == 0
+}
+
+// matchesBloom tests a single bloom against every filter slot using the
+// same 3-bit-index convention bloomIndexes compiled it with, rather than
+// re-hashing the slot's values against the bloom directly.
+func (m *Matcher) matchesBloom(bloom *gethTypes.Bloom) bool {
+	for _, slot := range m.filters {
+		matched := false
+		for _, idxs := range slot {
+			if testBloomBit(bloom, idxs[0]) && testBloomBit(bloom, idxs[1]) && testBloomBit(bloom, idxs[2]) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// testBloomBit reports whether bit position idx is set in bloom, using the
+// same byte/bit convention as bloomIndexes.
+func testBloomBit(bloom *gethTypes.Bloom, idx uint) bool {
+	byteIdx := gethTypes.BloomByteLength - 1 - idx/8
+	bitIdx := idx % 8
+	return bloom[byteIdx]&(1<<bitIdx) != 0
+}
+
+// bitmapOffsets returns the bit offsets that are set in bitmap.
+func bitmapOffsets(bitmap []byte) []uint64 {
+	offsets := make([]uint64, 0, len(bitmap))
+	for byteIdx, b := range bitmap {
+		if b == 0 {
+			continue
+		}
+		for bit := 0; bit < 8; bit++ {
+			if b&(1<<(7-bit)) != 0 {
+				offsets = append(offsets, uint64(byteIdx*8+bit))
+			}
+		}
+	}
+	return offsets
+}
@@ -0,0 +1,197 @@
+package logs
+
+import (
+	"testing"
+
+	"github.com/onflow/go-ethereum/common"
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-evm-gateway/models"
+)
+
+// fakeBitmapSource is an in-memory BitmapSource for exercising the Matcher
+// without a pebble store.
+type fakeBitmapSource struct {
+	sections uint64
+	size     uint64
+	bitmaps  map[uint64]map[uint16][]byte // section -> bit -> bitmap
+}
+
+func (f *fakeBitmapSource) Sections() (uint64, uint64, error) {
+	return f.sections, f.size, nil
+}
+
+func (f *fakeBitmapSource) Bitmap(section uint64, bit uint16) ([]byte, error) {
+	bm, ok := f.bitmaps[section][bit]
+	if !ok {
+		return make([]byte, f.size/8), nil
+	}
+	return bm, nil
+}
+
+func newFakeSource(size uint64) *fakeBitmapSource {
+	return &fakeBitmapSource{
+		size:    size,
+		bitmaps: make(map[uint64]map[uint16][]byte),
+	}
+}
+
+// setBlock marks that block `offset` within `section` has the address/topic
+// bloom bits set for `data`.
+func (f *fakeBitmapSource) setBlock(section uint64, offset uint, data []byte) {
+	idxs := bloomIndexes(data)
+	if f.bitmaps[section] == nil {
+		f.bitmaps[section] = make(map[uint16][]byte)
+	}
+	for _, idx := range idxs {
+		bit := uint16(idx)
+		if f.bitmaps[section][bit] == nil {
+			f.bitmaps[section][bit] = make([]byte, f.size/8)
+		}
+		f.bitmaps[section][bit][offset/8] |= 1 << (7 - offset%8)
+	}
+}
+
+func TestMatcher_NoCriteriaMatchesEverything(t *testing.T) {
+	source := newFakeSource(8)
+	source.sections = 1
+
+	m := NewMatcher(source, nil, nil)
+
+	candidates, ok, err := m.Candidates(0, 7)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, candidates, 8)
+}
+
+func TestMatcher_AddressMatch(t *testing.T) {
+	addr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	source := newFakeSource(8)
+	source.sections = 1
+	source.setBlock(0, 2, addr.Bytes())
+	source.setBlock(0, 5, other.Bytes())
+
+	m := NewMatcher(source, []common.Address{addr}, nil)
+
+	candidates, ok, err := m.Candidates(0, 7)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []uint64{2}, candidates)
+}
+
+func TestMatcher_AddressAndTopicMustBothMatch(t *testing.T) {
+	addr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	topic := common.HexToHash("0xaaaa")
+
+	source := newFakeSource(8)
+	source.sections = 1
+	// block 1 has the address but not the topic, block 3 has both.
+	source.setBlock(0, 1, addr.Bytes())
+	source.setBlock(0, 3, addr.Bytes())
+	source.setBlock(0, 3, topic.Bytes())
+
+	m := NewMatcher(source, []common.Address{addr}, [][]common.Hash{{topic}})
+
+	candidates, ok, err := m.Candidates(0, 7)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []uint64{3}, candidates)
+}
+
+func TestMatcher_FallsBackWhenNotYetIndexed(t *testing.T) {
+	source := newFakeSource(8)
+	source.sections = 0 // nothing indexed yet
+
+	m := NewMatcher(source, nil, nil)
+
+	candidates, ok, err := m.Candidates(0, 7)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Nil(t, candidates)
+}
+
+func TestMatcher_InvalidRange(t *testing.T) {
+	source := newFakeSource(8)
+	m := NewMatcher(source, nil, nil)
+
+	_, _, err := m.Candidates(10, 5)
+	require.ErrorContains(t, err, "bigger than end value")
+}
+
+// fakeBlockRangeBlooms is an in-memory BlockRangeBlooms for exercising the
+// linear-scan fallback MatchHeights uses for a range's unindexed tail.
+type fakeBlockRangeBlooms struct {
+	blooms map[uint64][]*gethTypes.Bloom
+}
+
+func (f *fakeBlockRangeBlooms) BloomsForBlockRange(start, end uint64) ([]*models.BloomsHeight, error) {
+	var out []*models.BloomsHeight
+	for h := start; h <= end; h++ {
+		if bl, ok := f.blooms[h]; ok {
+			out = append(out, &models.BloomsHeight{Height: h, Blooms: bl})
+		}
+	}
+	return out, nil
+}
+
+func TestMatcher_MatchHeights_UsesIndexAndFallsBackForTail(t *testing.T) {
+	addr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	source := newFakeSource(8)
+	source.sections = 1 // heights [0, 7] are indexed
+	source.setBlock(0, 2, addr.Bytes())
+
+	var matchingBloom gethTypes.Bloom
+	matchingBloom.Add(addr.Bytes())
+	var otherBloom gethTypes.Bloom
+	otherBloom.Add(other.Bytes())
+
+	fallback := &fakeBlockRangeBlooms{blooms: map[uint64][]*gethTypes.Bloom{
+		9:  {&matchingBloom}, // unindexed tail, matches
+		10: {&otherBloom},    // unindexed tail, doesn't match
+	}}
+
+	m := NewMatcher(source, []common.Address{addr}, nil)
+
+	heights, err := m.MatchHeights(fallback, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{2, 9}, heights)
+}
+
+func TestMatcher_MatchHeights_FallsBackEntirelyWhenNotYetIndexed(t *testing.T) {
+	addr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	source := newFakeSource(8)
+	source.sections = 0 // nothing indexed yet
+
+	var matchingBloom gethTypes.Bloom
+	matchingBloom.Add(addr.Bytes())
+
+	fallback := &fakeBlockRangeBlooms{blooms: map[uint64][]*gethTypes.Bloom{
+		3: {&matchingBloom},
+	}}
+
+	m := NewMatcher(source, []common.Address{addr}, nil)
+
+	heights, err := m.MatchHeights(fallback, 0, 5)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{3}, heights)
+}
+
+func TestBloomIndexes_Deterministic(t *testing.T) {
+	addr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	var b gethTypes.Bloom
+	b.Add(addr.Bytes())
+
+	idxs := bloomIndexes(addr.Bytes())
+	for _, idx := range idxs {
+		byteIdx := gethTypes.BloomByteLength - 1 - idx/8
+		bitIdx := idx % 8
+		require.NotZero(t, b[byteIdx]&(1<<bitIdx))
+	}
+}
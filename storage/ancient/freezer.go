@@ -0,0 +1,199 @@
+package ancient
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/onflow/flow-evm-gateway/storage"
+	errs "github.com/onflow/flow-evm-gateway/storage/errors"
+)
+
+// defaultMaxTableFileSize is the rollover threshold for a single data file
+// within a table, matching go-ethereum's freezer default.
+const defaultMaxTableFileSize = 2 * 1024 * 1024 * 1024 // 2 GiB
+
+// Freezer is the on-disk implementation of storage.Freezer. It owns one
+// table per registered name, all sharing the same height axis: item n in
+// every table corresponds to EVM height tail+n.
+type Freezer struct {
+	mux sync.RWMutex
+
+	tables     map[string]*table
+	tableOrder []string
+
+	tailFile *os.File // single uint64 holding the height of item 0
+	tail     uint64
+}
+
+// Open creates or reopens a freezer rooted at dir, with one table per name
+// in tables (e.g. "blocks", "receipts", "blooms", "tx-to-height"). Table
+// order matters: Append's items must be given in this same order.
+func Open(dir string, tables []string) (*Freezer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create freezer directory: %w", err)
+	}
+
+	f := &Freezer{
+		tables:     make(map[string]*table, len(tables)),
+		tableOrder: tables,
+	}
+
+	tailPath := filepath.Join(dir, "FREEZER_TAIL")
+	tailFile, err := os.OpenFile(tailPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open freezer tail file: %w", err)
+	}
+	f.tailFile = tailFile
+
+	// a fresh freezer has an empty tail file, which reads back as all
+	// zeros; the tail is then properly set by the first Append.
+	tailBytes := make([]byte, 8)
+	_, _ = tailFile.ReadAt(tailBytes, 0)
+	f.tail = binary.BigEndian.Uint64(tailBytes)
+
+	for _, name := range tables {
+		t, err := openTable(dir, name, defaultMaxTableFileSize)
+		if err != nil {
+			return nil, err
+		}
+		f.tables[name] = t
+	}
+
+	return f, nil
+}
+
+var _ storage.Freezer = &Freezer{}
+
+// Append implements storage.Freezer.
+func (f *Freezer) Append(height uint64, items [][]byte) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if len(items) != len(f.tableOrder) {
+		return fmt.Errorf(
+			"freezer append expected %d items (one per table), got %d",
+			len(f.tableOrder),
+			len(items),
+		)
+	}
+
+	head, err := f.headLocked()
+	if err != nil {
+		return err
+	}
+
+	// first ever append sets the tail.
+	empty := head == f.tail
+	if !empty && height != head {
+		return fmt.Errorf("freezer append out of order: expected height %d, got %d", head, height)
+	}
+	if empty {
+		if err := f.setTail(height); err != nil {
+			return err
+		}
+	}
+
+	for i, name := range f.tableOrder {
+		if err := f.tables[name].append(items[i]); err != nil {
+			return fmt.Errorf("failed to append to freezer table %s at height %d: %w", name, height, err)
+		}
+	}
+
+	return nil
+}
+
+// Retrieve implements storage.Freezer.
+func (f *Freezer) Retrieve(table string, height uint64) ([]byte, error) {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+
+	t, ok := f.tables[table]
+	if !ok {
+		return nil, fmt.Errorf("unknown freezer table: %s", table)
+	}
+
+	if height < f.tail {
+		return nil, errs.NotFound
+	}
+
+	return t.retrieve(height - f.tail)
+}
+
+// TruncateHead implements storage.Freezer.
+func (f *Freezer) TruncateHead(height uint64) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if height < f.tail {
+		return fmt.Errorf("cannot truncate head to %d below tail %d", height, f.tail)
+	}
+
+	for _, name := range f.tableOrder {
+		if err := f.tables[name].truncateHead(height - f.tail); err != nil {
+			return fmt.Errorf("failed to truncate freezer table %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// TruncateTail implements storage.Freezer. No table supports dropping items
+// from the front of its data log without rewriting the file, so a height at
+// or below the current tail is a no-op (nothing lies below the tail) and any
+// higher height returns an error without discarding anything.
+func (f *Freezer) TruncateTail(height uint64) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if height <= f.tail {
+		return nil
+	}
+	return fmt.Errorf("freezer: truncating the tail forward is not supported")
+}
+
+// Frozen implements storage.Freezer.
+func (f *Freezer) Frozen() (tail uint64, head uint64, err error) {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+
+	h, err := f.headLocked()
+	if err != nil {
+		return 0, 0, err
+	}
+	return f.tail, h, nil
+}
+
+func (f *Freezer) headLocked() (uint64, error) {
+	if len(f.tableOrder) == 0 {
+		return f.tail, nil
+	}
+	// all tables are kept aligned by Append, so any one of them reports the
+	// common item count.
+	first := f.tables[f.tableOrder[0]]
+	return f.tail + first.head, nil
+}
+
+func (f *Freezer) setTail(height uint64) error {
+	f.tail = height
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, height)
+	if _, err := f.tailFile.WriteAt(buf, 0); err != nil {
+		return err
+	}
+	return f.tailFile.Sync()
+}
+
+// Close implements storage.Freezer.
+func (f *Freezer) Close() error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	for _, name := range f.tableOrder {
+		if err := f.tables[name].close(); err != nil {
+			return err
+		}
+	}
+	return f.tailFile.Close()
+}
@@ -0,0 +1,104 @@
+package ancient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezer_AppendAndRetrieve(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := Open(dir, []string{"blocks", "receipts"})
+	require.NoError(t, err)
+	defer f.Close()
+
+	require.NoError(t, f.Append(10, [][]byte{[]byte("block-10"), []byte("receipts-10")}))
+	require.NoError(t, f.Append(11, [][]byte{[]byte("block-11"), []byte("receipts-11")}))
+
+	block, err := f.Retrieve("blocks", 10)
+	require.NoError(t, err)
+	require.Equal(t, "block-10", string(block))
+
+	receipts, err := f.Retrieve("receipts", 11)
+	require.NoError(t, err)
+	require.Equal(t, "receipts-11", string(receipts))
+
+	tail, head, err := f.Frozen()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), tail)
+	require.Equal(t, uint64(12), head)
+}
+
+func TestFreezer_AppendOutOfOrder(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := Open(dir, []string{"blocks"})
+	require.NoError(t, err)
+	defer f.Close()
+
+	require.NoError(t, f.Append(5, [][]byte{[]byte("a")}))
+	err = f.Append(7, [][]byte{[]byte("b")})
+	require.ErrorContains(t, err, "out of order")
+}
+
+func TestFreezer_RetrieveBelowTail(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := Open(dir, []string{"blocks"})
+	require.NoError(t, err)
+	defer f.Close()
+
+	require.NoError(t, f.Append(100, [][]byte{[]byte("a")}))
+
+	_, err = f.Retrieve("blocks", 50)
+	require.Error(t, err)
+}
+
+func TestFreezer_TruncateHead(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := Open(dir, []string{"blocks"})
+	require.NoError(t, err)
+	defer f.Close()
+
+	for h := uint64(0); h < 5; h++ {
+		require.NoError(t, f.Append(h, [][]byte{[]byte{byte(h)}}))
+	}
+
+	require.NoError(t, f.TruncateHead(3))
+
+	_, head, err := f.Frozen()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), head)
+
+	_, err = f.Retrieve("blocks", 3)
+	require.Error(t, err)
+
+	val, err := f.Retrieve("blocks", 2)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, val)
+}
+
+func TestFreezer_Reopen(t *testing.T) {
+	dir := t.TempDir()
+
+	f, err := Open(dir, []string{"blocks"})
+	require.NoError(t, err)
+	require.NoError(t, f.Append(0, [][]byte{[]byte("genesis")}))
+	require.NoError(t, f.Append(1, [][]byte{[]byte("second")}))
+	require.NoError(t, f.Close())
+
+	reopened, err := Open(dir, []string{"blocks"})
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	val, err := reopened.Retrieve("blocks", 1)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(val))
+
+	require.NoError(t, reopened.Append(2, [][]byte{[]byte("third")}))
+	val, err = reopened.Retrieve("blocks", 2)
+	require.NoError(t, err)
+	require.Equal(t, "third", string(val))
+}
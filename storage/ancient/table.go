@@ -0,0 +1,319 @@
+// Package ancient implements storage.Freezer: an append-only, compressed
+// flat-file cold tier modeled on go-ethereum's ancient store.
+package ancient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	errs "github.com/onflow/flow-evm-gateway/storage/errors"
+)
+
+// indexEntrySize is the on-disk size of a single index entry: the file
+// number the item lives in, plus the byte offset marking the end of the
+// item within that file (so item i occupies [entries[i].offset,
+// entries[i+1].offset) in file entries[i+1].fileNum, or [0,
+// entries[i+1].offset) when the two entries name different files).
+const indexEntrySize = 8 // 4 bytes file number + 4 bytes offset
+
+type indexEntry struct {
+	fileNum uint32
+	offset  uint32
+}
+
+func (e indexEntry) marshal() []byte {
+	buf := make([]byte, indexEntrySize)
+	binary.BigEndian.PutUint32(buf[:4], e.fileNum)
+	binary.BigEndian.PutUint32(buf[4:], e.offset)
+	return buf
+}
+
+func unmarshalIndexEntry(buf []byte) indexEntry {
+	return indexEntry{
+		fileNum: binary.BigEndian.Uint32(buf[:4]),
+		offset:  binary.BigEndian.Uint32(buf[4:]),
+	}
+}
+
+// table is a single append-only, gzip-compressed data log with a matching
+// fixed-size offset index, rolling over to a new data file once the current
+// one reaches maxFileSize.
+type table struct {
+	mux sync.RWMutex
+
+	dir     string
+	name    string
+	maxSize uint32
+
+	index *os.File // sequence of indexEntry, one per item plus a leading zero entry
+
+	head       uint64 // number of items appended so far (tail-relative; see freezer for the height offset)
+	headFile   *os.File
+	headNum    uint32
+	headOffset uint32
+}
+
+func openTable(dir, name string, maxSize uint32) (*table, error) {
+	t := &table{dir: dir, name: name, maxSize: maxSize}
+
+	idx, err := os.OpenFile(t.indexPath(), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index file for table %s: %w", name, err)
+	}
+	t.index = idx
+
+	if err := t.repair(); err != nil {
+		idx.Close()
+		return nil, fmt.Errorf("failed to repair table %s: %w", name, err)
+	}
+
+	if err := t.openHeadFile(); err != nil {
+		idx.Close()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// repair truncates a partially written trailing index entry (crash between
+// writing the data and the index is the only inconsistency this format can
+// have, since the index write always happens last).
+func (t *table) repair() error {
+	info, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+
+	size := info.Size()
+	if size == 0 {
+		// seed with the leading zero entry marking the start of item 0.
+		if _, err := t.index.WriteAt(indexEntry{}.marshal(), 0); err != nil {
+			return err
+		}
+		t.head = 0
+		return nil
+	}
+
+	valid := (size / indexEntrySize) * indexEntrySize
+	if valid != size {
+		if err := t.index.Truncate(valid); err != nil {
+			return err
+		}
+	}
+
+	t.head = uint64(valid/indexEntrySize) - 1
+
+	last := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(last, valid-indexEntrySize); err != nil {
+		return err
+	}
+	entry := unmarshalIndexEntry(last)
+	t.headNum = entry.fileNum
+	t.headOffset = entry.offset
+
+	// the data file itself may also have a partial trailing write if the
+	// process crashed between appending data and writing the index entry
+	// for it; since the index is only ever updated after a successful data
+	// write, truncating the data file to the last known-good offset is
+	// always safe.
+	dataPath := t.dataPath(entry.fileNum)
+	if f, err := os.OpenFile(dataPath, os.O_RDWR|os.O_CREATE, 0o644); err == nil {
+		if err := f.Truncate(int64(entry.offset)); err != nil {
+			f.Close()
+			return err
+		}
+		f.Close()
+	}
+
+	return nil
+}
+
+func (t *table) openHeadFile() error {
+	f, err := os.OpenFile(t.dataPath(t.headNum), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	t.headFile = f
+	return nil
+}
+
+func (t *table) dataPath(fileNum uint32) string {
+	return filepath.Join(t.dir, fmt.Sprintf("%s.%04d.cdat", t.name, fileNum))
+}
+
+func (t *table) indexPath() string {
+	return filepath.Join(t.dir, fmt.Sprintf("%s.cidx", t.name))
+}
+
+// append compresses and writes a single item, rolling over to a new data
+// file first if it would push the current one past maxSize.
+func (t *table) append(item []byte) error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	compressed, err := gzipCompress(item)
+	if err != nil {
+		return err
+	}
+
+	if t.headOffset > 0 && t.headOffset+uint32(len(compressed)) > t.maxSize {
+		if err := t.rollover(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := t.headFile.Write(compressed); err != nil {
+		return fmt.Errorf("failed to write item to table %s: %w", t.name, err)
+	}
+	if err := t.headFile.Sync(); err != nil {
+		return fmt.Errorf("failed to fsync table %s data file: %w", t.name, err)
+	}
+
+	t.headOffset += uint32(len(compressed))
+	entry := indexEntry{fileNum: t.headNum, offset: t.headOffset}
+
+	if _, err := t.index.WriteAt(entry.marshal(), int64((t.head+1)*indexEntrySize)); err != nil {
+		return fmt.Errorf("failed to write index entry for table %s: %w", t.name, err)
+	}
+	if err := t.index.Sync(); err != nil {
+		return fmt.Errorf("failed to fsync table %s index: %w", t.name, err)
+	}
+
+	t.head++
+	return nil
+}
+
+func (t *table) rollover() error {
+	if err := t.headFile.Close(); err != nil {
+		return err
+	}
+	t.headNum++
+	t.headOffset = 0
+	return t.openHeadFile()
+}
+
+// retrieve reads back item number `n` (0-indexed).
+func (t *table) retrieve(n uint64) ([]byte, error) {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+
+	if n >= t.head {
+		return nil, errs.NotFound
+	}
+
+	startBuf := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(startBuf, int64(n*indexEntrySize)); err != nil {
+		return nil, err
+	}
+	endBuf := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(endBuf, int64((n+1)*indexEntrySize)); err != nil {
+		return nil, err
+	}
+
+	start := unmarshalIndexEntry(startBuf)
+	end := unmarshalIndexEntry(endBuf)
+
+	var data []byte
+	var err error
+	if start.fileNum == end.fileNum {
+		data, err = t.readRange(end.fileNum, start.offset, end.offset)
+	} else {
+		// the item is the whole of the new file from offset 0, since
+		// rollover always starts a file empty before the next append.
+		data, err = t.readRange(end.fileNum, 0, end.offset)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return gzipDecompress(data)
+}
+
+func (t *table) readRange(fileNum uint32, start, end uint32) ([]byte, error) {
+	f, err := os.Open(t.dataPath(fileNum))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, end-start)
+	if _, err := f.ReadAt(buf, int64(start)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// truncateHead discards all items with index >= n.
+func (t *table) truncateHead(n uint64) error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if n >= t.head {
+		return nil
+	}
+
+	entryBuf := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(entryBuf, int64(n*indexEntrySize)); err != nil {
+		return err
+	}
+	entry := unmarshalIndexEntry(entryBuf)
+
+	if err := t.index.Truncate(int64((n + 1) * indexEntrySize)); err != nil {
+		return err
+	}
+
+	if err := t.headFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Truncate(t.dataPath(entry.fileNum), int64(entry.offset)); err != nil {
+		return err
+	}
+
+	// remove any now-orphaned rollover files ahead of the truncation point.
+	for fileNum := entry.fileNum + 1; fileNum <= t.headNum; fileNum++ {
+		_ = os.Remove(t.dataPath(fileNum))
+	}
+
+	t.headNum = entry.fileNum
+	t.headOffset = entry.offset
+	t.head = n
+
+	return t.openHeadFile()
+}
+
+func (t *table) close() error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if err := t.headFile.Close(); err != nil {
+		return err
+	}
+	return t.index.Close()
+}
+
+func gzipCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gzipDecompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,263 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/onflow/flow-go/fvm/evm/types"
+	"github.com/onflow/go-ethereum/common"
+	"github.com/rs/zerolog"
+)
+
+// ChainIndexerBackend is implemented by subsystems that want to build a
+// section-based index on top of the EVM block stream, e.g. the bloombits
+// indexer, a future trace index, or an ERC-20 transfer index. A ChainIndexer
+// drives a backend through Reset/Process/Commit once per section, the same
+// shape as go-ethereum's core/chain_indexer.go.
+type ChainIndexerBackend interface {
+	// Reset starts a new section, given its number and the hash of the last
+	// block of the previous section (the zero hash if there isn't one yet).
+	// A backend that keeps in-progress accumulation state should clear it
+	// here.
+	Reset(section uint64, prevHead common.Hash)
+
+	// Process indexes a single block's header into the section currently
+	// being built.
+	Process(header *types.Block) error
+
+	// Commit finalizes and persists the section built up since Reset.
+	Commit() error
+}
+
+// SectionCheckpointStore persists, per indexer, the hash of the last block
+// of every section that has been fully committed, so a ChainIndexer can
+// resume after a crash without reprocessing sections it already finished,
+// and can tell whether a stored section was built on a chain that a spork
+// transition or reorg has since rewound past.
+type SectionCheckpointStore interface {
+	GetSectionHead(indexerID string, section uint64) (common.Hash, bool, error)
+	SetSectionHead(indexerID string, section uint64, head common.Hash) error
+	DeleteSectionHead(indexerID string, section uint64) error
+}
+
+// BlockSource is the read side of the block indexer a ChainIndexer follows.
+type BlockSource interface {
+	GetByHeight(height uint64) (*types.Block, error)
+}
+
+// ChainIndexer divides the EVM height space into fixed-size sections and
+// drives a ChainIndexerBackend through each section once every block in it
+// is available. It resumes from its last committed section on restart, and
+// can chain to child indexers whose own indexing depends on this one having
+// finished a section (e.g. bloombits depends on receipts being indexed).
+type ChainIndexer struct {
+	id          string
+	sectionSize uint64
+
+	backend ChainIndexerBackend
+	blocks  BlockSource
+	store   SectionCheckpointStore
+	log     zerolog.Logger
+
+	mux            sync.Mutex
+	storedSections uint64 // number of sections fully committed so far
+
+	children []*ChainIndexer
+
+	newHead chan uint64 // buffered 1; always holds only the latest known tip
+	done    chan struct{}
+}
+
+// NewChainIndexer creates a ChainIndexer for the given backend and resumes
+// storedSections from store by probing for the lowest section whose head
+// isn't checkpointed yet, so a restart picks up where a crash left off
+// instead of reprocessing from genesis. id must be unique among indexers
+// sharing the same SectionCheckpointStore, since it's used as part of the
+// checkpoint key.
+func NewChainIndexer(
+	id string,
+	sectionSize uint64,
+	backend ChainIndexerBackend,
+	blocks BlockSource,
+	store SectionCheckpointStore,
+	log zerolog.Logger,
+) *ChainIndexer {
+	c := &ChainIndexer{
+		id:          id,
+		sectionSize: sectionSize,
+		backend:     backend,
+		blocks:      blocks,
+		store:       store,
+		log:         log.With().Str("component", "chain-indexer").Str("indexer", id).Logger(),
+		newHead:     make(chan uint64, 1),
+		done:        make(chan struct{}),
+	}
+
+	for {
+		_, ok, err := store.GetSectionHead(id, c.storedSections)
+		if err != nil {
+			c.log.Error().Err(err).Uint64("section", c.storedSections).Msg("failed to read stored section head, resuming from genesis")
+			c.storedSections = 0
+			break
+		}
+		if !ok {
+			break
+		}
+		c.storedSections++
+	}
+
+	return c
+}
+
+// AddChildIndexer registers child to be notified every time this indexer
+// finishes a new section, so indexes that depend on this one's output (e.g.
+// bloombits depending on receipts) can start processing right away instead
+// of polling.
+func (c *ChainIndexer) AddChildIndexer(child *ChainIndexer) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.children = append(c.children, child)
+}
+
+// Start resumes from the last committed section (if any) and launches the
+// background goroutine that processes new sections as NewHead reports
+// further tip advances. Call Stop to terminate it.
+func (c *ChainIndexer) Start() {
+	go func() {
+		for {
+			select {
+			case <-c.done:
+				return
+			case head := <-c.newHead:
+				if err := c.processUpTo(head); err != nil {
+					c.log.Error().Err(err).Uint64("head", head).Msg("failed to process sections")
+				}
+			}
+		}
+	}()
+}
+
+// Stop terminates the background processing goroutine.
+func (c *ChainIndexer) Stop() {
+	close(c.done)
+}
+
+// NewHead reports a new chain tip to the indexer. It never blocks: if the
+// goroutine hasn't caught up to the previous notification yet, this one
+// simply replaces it, since only the latest tip matters.
+func (c *ChainIndexer) NewHead(height uint64) {
+	select {
+	case c.newHead <- height:
+	default:
+		select {
+		case <-c.newHead:
+		default:
+		}
+		c.newHead <- height
+	}
+}
+
+// Sections reports how many sections have been fully committed.
+func (c *ChainIndexer) Sections() uint64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.storedSections
+}
+
+// Progress reports how many sections have been fully committed and the
+// size of each section, so progress-reporting code (e.g. AdminIndexerAPI)
+// can treat a ChainIndexer the same way as any other section-based indexer
+// such as pebble.BloombitsIndexer, without caring which one it's given.
+func (c *ChainIndexer) Progress() (sections uint64, size uint64, err error) {
+	return c.Sections(), c.sectionSize, nil
+}
+
+// processUpTo commits every section that's now fully covered by head.
+func (c *ChainIndexer) processUpTo(head uint64) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for {
+		section := c.storedSections
+		sectionEnd := (section+1)*c.sectionSize - 1
+		if sectionEnd > head {
+			return nil
+		}
+
+		prevHead := common.Hash{}
+		if section > 0 {
+			h, ok, err := c.store.GetSectionHead(c.id, section-1)
+			if err != nil {
+				return fmt.Errorf("failed to read previous section head: %w", err)
+			}
+			if !ok {
+				return fmt.Errorf("missing checkpoint for section %d", section-1)
+			}
+			prevHead = h
+		}
+
+		c.backend.Reset(section, prevHead)
+
+		var lastHash common.Hash
+		for height := section * c.sectionSize; height <= sectionEnd; height++ {
+			block, err := c.blocks.GetByHeight(height)
+			if err != nil {
+				return fmt.Errorf("failed to get block at height %d: %w", height, err)
+			}
+
+			if err := c.backend.Process(block); err != nil {
+				return fmt.Errorf("failed to process block at height %d: %w", height, err)
+			}
+
+			hash, err := block.Hash()
+			if err != nil {
+				return fmt.Errorf("failed to hash block at height %d: %w", height, err)
+			}
+			lastHash = hash
+		}
+
+		if err := c.backend.Commit(); err != nil {
+			return fmt.Errorf("failed to commit section %d: %w", section, err)
+		}
+
+		if err := c.store.SetSectionHead(c.id, section, lastHash); err != nil {
+			return fmt.Errorf("failed to checkpoint section %d: %w", section, err)
+		}
+
+		c.storedSections++
+		c.log.Debug().Uint64("section", section).Msg("indexed section")
+
+		for _, child := range c.children {
+			child.NewHead(sectionEnd)
+		}
+	}
+}
+
+// Rewind discards committed sections at or above the section containing
+// height, used when CrossSporkClient surfaces a cross-spork boundary change
+// that invalidates previously indexed blocks. Child indexers are rewound
+// first since their state may depend on sections this indexer is about to
+// drop.
+func (c *ChainIndexer) Rewind(height uint64) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for _, child := range c.children {
+		if err := child.Rewind(height); err != nil {
+			return err
+		}
+	}
+
+	section := height / c.sectionSize
+	for s := section; s < c.storedSections; s++ {
+		if err := c.store.DeleteSectionHead(c.id, s); err != nil {
+			return fmt.Errorf("failed to delete checkpoint for section %d: %w", s, err)
+		}
+	}
+
+	if section < c.storedSections {
+		c.storedSections = section
+	}
+
+	return nil
+}
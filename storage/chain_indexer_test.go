@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/onflow/flow-go/fvm/evm/types"
+	"github.com/onflow/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// memCheckpointStore is an in-memory SectionCheckpointStore for tests.
+type memCheckpointStore struct {
+	heads map[string]common.Hash
+}
+
+func newMemCheckpointStore() *memCheckpointStore {
+	return &memCheckpointStore{heads: make(map[string]common.Hash)}
+}
+
+func (m *memCheckpointStore) key(id string, section uint64) string {
+	return fmt.Sprintf("%s|%d", id, section)
+}
+
+func (m *memCheckpointStore) GetSectionHead(id string, section uint64) (common.Hash, bool, error) {
+	h, ok := m.heads[m.key(id, section)]
+	return h, ok, nil
+}
+
+func (m *memCheckpointStore) SetSectionHead(id string, section uint64, head common.Hash) error {
+	m.heads[m.key(id, section)] = head
+	return nil
+}
+
+func (m *memCheckpointStore) DeleteSectionHead(id string, section uint64) error {
+	delete(m.heads, m.key(id, section))
+	return nil
+}
+
+// fakeBackend records the blocks it was asked to process, per section.
+type fakeBackend struct {
+	sections [][]uint64
+	current  []uint64
+}
+
+func (b *fakeBackend) Reset(section uint64, prevHead common.Hash) {
+	b.current = nil
+}
+
+func (b *fakeBackend) Process(header *types.Block) error {
+	b.current = append(b.current, header.Height)
+	return nil
+}
+
+func (b *fakeBackend) Commit() error {
+	b.sections = append(b.sections, b.current)
+	return nil
+}
+
+// fakeBlockSource serves blocks from an in-memory map keyed by height.
+type fakeBlockSource struct {
+	blocks map[uint64]*types.Block
+}
+
+func (f *fakeBlockSource) GetByHeight(height uint64) (*types.Block, error) {
+	return f.blocks[height], nil
+}
+
+func newFakeBlockSource(upTo uint64) *fakeBlockSource {
+	blocks := make(map[uint64]*types.Block, upTo+1)
+	for h := uint64(0); h <= upTo; h++ {
+		blocks[h] = &types.Block{Height: h}
+	}
+	return &fakeBlockSource{blocks: blocks}
+}
+
+func TestChainIndexer_ProcessesCompleteSectionsOnly(t *testing.T) {
+	backend := &fakeBackend{}
+	blocks := newFakeBlockSource(9)
+	store := newMemCheckpointStore()
+
+	indexer := NewChainIndexer("test", 4, backend, blocks, store, zerolog.Nop())
+
+	require.NoError(t, indexer.processUpTo(9))
+
+	require.Equal(t, uint64(2), indexer.Sections())
+	require.Equal(t, []uint64{0, 1, 2, 3}, backend.sections[0])
+	require.Equal(t, []uint64{4, 5, 6, 7}, backend.sections[1])
+}
+
+func TestChainIndexer_ResumesFromStoredSections(t *testing.T) {
+	backend := &fakeBackend{}
+	blocks := newFakeBlockSource(7)
+	store := newMemCheckpointStore()
+	require.NoError(t, store.SetSectionHead("test", 0, common.HexToHash("0xaa")))
+
+	indexer := NewChainIndexer("test", 4, backend, blocks, store, zerolog.Nop())
+	require.Equal(t, uint64(1), indexer.Sections())
+
+	require.NoError(t, indexer.processUpTo(7))
+
+	require.Equal(t, uint64(2), indexer.Sections())
+	require.Len(t, backend.sections, 1)
+	require.Equal(t, []uint64{4, 5, 6, 7}, backend.sections[0])
+}
+
+func TestChainIndexer_NotifiesChildOnSectionCompletion(t *testing.T) {
+	parentBackend := &fakeBackend{}
+	childBackend := &fakeBackend{}
+	blocks := newFakeBlockSource(7)
+	store := newMemCheckpointStore()
+
+	parent := NewChainIndexer("parent", 4, parentBackend, blocks, store, zerolog.Nop())
+	child := NewChainIndexer("child", 4, childBackend, blocks, store, zerolog.Nop())
+	parent.AddChildIndexer(child)
+
+	require.NoError(t, parent.processUpTo(7))
+
+	select {
+	case head := <-child.newHead:
+		require.Equal(t, uint64(7), head)
+	default:
+		t.Fatal("expected child to be notified of new head")
+	}
+}
+
+func TestChainIndexer_Rewind(t *testing.T) {
+	backend := &fakeBackend{}
+	blocks := newFakeBlockSource(7)
+	store := newMemCheckpointStore()
+
+	indexer := NewChainIndexer("test", 4, backend, blocks, store, zerolog.Nop())
+	require.NoError(t, indexer.processUpTo(7))
+	require.Equal(t, uint64(2), indexer.Sections())
+
+	require.NoError(t, indexer.Rewind(5))
+
+	require.Equal(t, uint64(1), indexer.Sections())
+	_, ok, err := store.GetSectionHead("test", 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,39 @@
+package storage
+
+// Freezer is a two-tier, append-only cold storage interface modeled on
+// go-ethereum's ancient store. It is used to move data that is unlikely to
+// be read again (blocks and receipts older than the configured finality
+// window) out of pebble and into flat, compressed files, so the hot pebble
+// store doesn't grow without bound over the lifetime of a gateway.
+//
+// A Freezer is created with a fixed, ordered list of tables (e.g. "blocks",
+// "receipts", "blooms", "tx-to-height") and every Append writes one record
+// per table for a single height, keeping all tables aligned on the same
+// height axis.
+type Freezer interface {
+	// Append commits one record to each registered table for the given
+	// height. len(items) must equal the number of registered tables, in
+	// the order they were registered. Height must equal the current head
+	// (one past the last appended height), i.e. appends are strictly
+	// sequential; the first Append to an empty freezer sets the tail.
+	Append(height uint64, items [][]byte) error
+
+	// Retrieve reads back the item stored for a table at a height. Returns
+	// storage/errors.NotFound if height falls outside [Tail, Head).
+	Retrieve(table string, height uint64) ([]byte, error)
+
+	// TruncateHead discards all items with height >= the given height.
+	TruncateHead(height uint64) error
+
+	// TruncateTail discards all items with height < the given height. This
+	// is currently unused by the gateway (nothing prunes the cold tier) but
+	// is part of the interface for parity with go-ethereum's freezer and
+	// for operators who want to bound disk usage further.
+	TruncateTail(height uint64) error
+
+	// Frozen reports the half-open height range [tail, head) currently held
+	// by the freezer.
+	Frozen() (tail uint64, head uint64, err error)
+
+	Close() error
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/onflow/flow-evm-gateway/storage"
@@ -30,6 +31,29 @@ type Blocks struct {
 	mux   sync.RWMutex
 	// todo LRU caching with size limit
 	heightCache map[byte]uint64
+
+	freezer  storage.Freezer
+	hotHits  atomic.Uint64
+	coldHits atomic.Uint64
+}
+
+// SetFreezer wires up the cold storage tier consulted once a height falls
+// below the frozen boundary migrated by a Migrator. It is optional: a
+// Blocks index with no freezer just always reads from pebble.
+func (b *Blocks) SetFreezer(freezer storage.Freezer) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.freezer = freezer
+}
+
+// ColdStats reports how many GetByHeight calls were served from pebble
+// versus the freezer, so operators can judge whether the configured
+// finality window matches their traffic's recency.
+func (b *Blocks) ColdStats() ColdStats {
+	return ColdStats{
+		HotHits:  b.hotHits.Load(),
+		ColdHits: b.coldHits.Load(),
+	}
 }
 
 func NewBlocks(store *Storage, opts ...BlockOption) (*Blocks, error) {
@@ -99,6 +123,22 @@ func (b *Blocks) GetByHeight(height uint64) (*types.Block, error) {
 		return nil, errs.NotFound
 	}
 
+	if b.freezer != nil {
+		boundary, err := frozenBoundary(b.store)
+		if err != nil {
+			return nil, err
+		}
+		if height < boundary {
+			val, err := b.freezer.Retrieve(freezerTableBlocks, height)
+			if err != nil {
+				return nil, err
+			}
+			b.coldHits.Add(1)
+			return types.NewBlockFromBytes(val)
+		}
+	}
+
+	b.hotHits.Add(1)
 	return b.getBlock(blockHeightKey, uint64Bytes(height))
 }
 
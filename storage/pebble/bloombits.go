@@ -0,0 +1,414 @@
+package pebble
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+	"github.com/rs/zerolog"
+
+	errs "github.com/onflow/flow-evm-gateway/storage/errors"
+)
+
+const (
+	// bloomBitsSectionSize is the number of consecutive EVM blocks a single
+	// bloombits section covers. Sections are only indexed once all of their
+	// blocks are available, so a smaller size makes indexed ranges available
+	// sooner at the cost of more index entries.
+	bloomBitsSectionSize = 4096
+
+	// bloomBitsLength is the number of bits in a go-ethereum bloom filter
+	// (2048 bits == 256 bytes).
+	bloomBitsLength = 2048
+)
+
+// Key layout, following the rest of this package's single-byte key codes
+// (see keys.go):
+//   - bloomBitsKey     || section (8 bytes) || bit index (2 bytes) => compressed bitmap
+//   - bloomBitsHeadKey                                              => last completed section (8 bytes)
+//
+// There's no per-bit head: all 2048 bitmaps of a section are written in one
+// batch, so a single section head is enough to make sure a partially
+// written section is never read as if it were complete.
+const (
+	bloomBitsKey     byte = 20
+	bloomBitsHeadKey byte = 21
+)
+
+// BloombitsIndexer builds and serves the rotated bloom-bits index described
+// in the go-ethereum `eth/filters` design: instead of storing one bloom
+// filter per block, it stores one bitmap per bloom bit position covering an
+// entire section of blocks. This lets `eth_getLogs` test a handful of
+// bitmaps instead of scanning every block's bloom in the requested range.
+type BloombitsIndexer struct {
+	store *Storage
+	log   zerolog.Logger
+
+	mux     sync.RWMutex
+	pending map[uint64]*bloomBitsSection // sections currently being built, keyed by section number
+
+	// next is the next EVM height that hasn't been folded into a section yet.
+	next atomic.Uint64
+
+	done chan struct{}
+}
+
+// bloomBitsSection accumulates the per-bit bitmaps for a single section while
+// blocks are still being processed. Once `head - sectionStart + 1` reaches
+// bloomBitsSectionSize, it is flushed to pebble and a new one is started.
+type bloomBitsSection struct {
+	number uint64
+	bits   [bloomBitsLength][]byte // one bit per row, bloomBitsSectionSize/8 bytes each, indexed by bit position
+}
+
+func newBloomBitsSection(number uint64) *bloomBitsSection {
+	s := &bloomBitsSection{number: number}
+	for i := range s.bits {
+		s.bits[i] = make([]byte, bloomBitsSectionSize/8)
+	}
+	return s
+}
+
+// set marks bit `bit` of the bloom filter as present for block `offset`
+// (0-indexed within the section). The byte/bit layout here must match
+// bloomIndexes in services/logs/matcher.go, which is what compiles filter
+// queries into indices against this same index.
+func (s *bloomBitsSection) set(offset uint, bloom *gethTypes.Bloom) {
+	for i := 0; i < bloomBitsLength; i++ {
+		byteIdx := gethTypes.BloomByteLength - 1 - i/8
+		bitIdx := uint(i % 8)
+		if bloom[byteIdx]&(1<<bitIdx) != 0 {
+			s.bits[i][offset/8] |= 1 << (7 - offset%8)
+		}
+	}
+}
+
+// truncate clears every bit at or after offset, used by Rollback to discard
+// the tail of a section whose later blocks were reorged away while keeping
+// the bits for the blocks before offset intact.
+func (s *bloomBitsSection) truncate(offset uint) {
+	for i := range s.bits {
+		for o := offset; o < bloomBitsSectionSize; o++ {
+			s.bits[i][o/8] &^= 1 << (7 - o%8)
+		}
+	}
+}
+
+// StartBloombitsIndexer creates a bloom-bits indexer and starts it
+// following blocks as they arrive, so eth_getLogs actually gets the
+// bloom-bits speedup: latestHeight comes from Blocks.LatestEVMHeight, and
+// each height's blooms come from Receipts.GetByBlockHeight, merging every
+// receipt's bloom in the block the same way Store does. The returned
+// indexer also satisfies BitmapSource (for logs.Matcher) and
+// api.SectionProgress (for AdminIndexerAPI). Call this once during gateway
+// startup, next to where Migrator.Start is called for the same Storage, and
+// pass the result as the BitmapSource for the logs.Matcher that backs
+// eth_getLogs plus as one of the indexers registered with AdminIndexerAPI.
+func StartBloombitsIndexer(store *Storage, blocks *Blocks, receipts *Receipts, log zerolog.Logger) *BloombitsIndexer {
+	indexer := NewBloombitsIndexer(store, log)
+
+	indexer.Start(blocks.LatestEVMHeight, func(height uint64) ([]*gethTypes.Bloom, error) {
+		rcpts, err := receipts.GetByBlockHeight(height)
+		if err != nil {
+			return nil, err
+		}
+
+		blooms := make([]*gethTypes.Bloom, len(rcpts))
+		for i, rcpt := range rcpts {
+			blooms[i] = &rcpt.Bloom
+		}
+		return blooms, nil
+	})
+
+	return indexer
+}
+
+// NewBloombitsIndexer creates a bloom-bits indexer backed by the provided
+// pebble store. Call Start to begin following the chain tip, or use
+// StartBloombitsIndexer to wire it up against Blocks/Receipts directly.
+func NewBloombitsIndexer(store *Storage, log zerolog.Logger) *BloombitsIndexer {
+	return &BloombitsIndexer{
+		store:   store,
+		log:     log.With().Str("component", "bloombits-indexer").Logger(),
+		pending: make(map[uint64]*bloomBitsSection),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start launches the background goroutine that indexes newly produced
+// blocks as they become available, driven by the block indexer's tip.
+// `latestHeight` should report the block indexer's current tip, and
+// `blooms` should return the per-transaction blooms stored for a height
+// (e.g. Receipts.GetByBlockHeight). Call Stop to terminate it.
+func (b *BloombitsIndexer) Start(latestHeight func() (uint64, error), blooms func(height uint64) ([]*gethTypes.Bloom, error)) {
+	head, err := b.lastCompletedSection()
+	if err != nil && !errors.Is(err, errs.NotFound) {
+		b.log.Error().Err(err).Msg("failed to read bloom-bits section head, starting from genesis")
+	}
+	if err == nil {
+		b.next.Store((head + 1) * bloomBitsSectionSize)
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-b.done:
+				return
+			case <-ticker.C:
+			}
+
+			for {
+				latest, err := latestHeight()
+				if err != nil {
+					b.log.Error().Err(err).Msg("failed to read latest height")
+					break
+				}
+
+				next := b.next.Load()
+				if next > latest {
+					break
+				}
+
+				bls, err := blooms(next)
+				if err != nil {
+					b.log.Error().Err(err).Uint64("height", next).Msg("failed to load blooms for height")
+					break
+				}
+
+				if err := b.index(next, bls); err != nil {
+					b.log.Error().Err(err).Uint64("height", next).Msg("failed to index blooms for height")
+					break
+				}
+
+				b.next.Add(1)
+			}
+		}
+	}()
+}
+
+// Stop terminates the background indexing goroutine.
+func (b *BloombitsIndexer) Stop() {
+	close(b.done)
+}
+
+// index folds the blooms of a single block (possibly several, one per
+// receipt) into the in-progress section, flushing the section once it is
+// full.
+func (b *BloombitsIndexer) index(height uint64, blooms []*gethTypes.Bloom) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	sectionNum := height / bloomBitsSectionSize
+	offset := uint(height % bloomBitsSectionSize)
+
+	section, ok := b.pending[sectionNum]
+	if !ok {
+		section = newBloomBitsSection(sectionNum)
+		b.pending[sectionNum] = section
+	}
+
+	// a block can carry more than one receipt bloom (one per transaction);
+	// OR them together since the section bit only needs to know whether the
+	// block *could* contain a matching log.
+	merged := gethTypes.Bloom{}
+	for _, bl := range blooms {
+		for i, bb := range bl {
+			merged[i] |= bb
+		}
+	}
+	section.set(offset, &merged)
+
+	if offset == bloomBitsSectionSize-1 {
+		if err := b.commitSection(section); err != nil {
+			return err
+		}
+		delete(b.pending, sectionNum)
+	}
+
+	return nil
+}
+
+// commitSection gzip-compresses each of the 2048 bitmaps and persists them
+// under their own key, then advances the section head so partial sections
+// are never mistaken for complete ones.
+func (b *BloombitsIndexer) commitSection(section *bloomBitsSection) error {
+	batch := b.store.db.NewBatch()
+	defer batch.Close()
+
+	for bit := 0; bit < bloomBitsLength; bit++ {
+		compressed, err := compressBitmap(section.bits[bit])
+		if err != nil {
+			return fmt.Errorf("failed to compress bloom-bits section %d bit %d: %w", section.number, bit, err)
+		}
+
+		if err := b.store.set(
+			bloomBitsKey,
+			bloomBitsStorageKey(section.number, uint16(bit)),
+			compressed,
+			batch,
+		); err != nil {
+			return fmt.Errorf("failed to store bloom-bits section %d bit %d: %w", section.number, bit, err)
+		}
+	}
+
+	if err := b.store.set(bloomBitsHeadKey, nil, uint64Bytes(section.number), batch); err != nil {
+		return fmt.Errorf("failed to store bloom-bits section head: %w", err)
+	}
+
+	return batch.Commit(pebble.Sync)
+}
+
+// lastCompletedSection returns the highest section number that has been
+// fully committed. Sections are 0-indexed, so a return value of 0 means
+// section 0 is complete; if no section has been committed yet it returns
+// errs.NotFound.
+func (b *BloombitsIndexer) lastCompletedSection() (uint64, error) {
+	val, err := b.store.get(bloomBitsHeadKey)
+	if err != nil {
+		if errors.Is(err, errs.NotFound) {
+			return 0, errs.NotFound
+		}
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(val), nil
+}
+
+// Sections reports the number of fully indexed sections and the section
+// size, used both by the Matcher to know how far it can rely on the index,
+// and by admin tooling to report indexing progress.
+func (b *BloombitsIndexer) Sections() (sections uint64, size uint64, err error) {
+	head, err := b.lastCompletedSection()
+	if err != nil {
+		if errors.Is(err, errs.NotFound) {
+			return 0, bloomBitsSectionSize, nil
+		}
+		return 0, 0, err
+	}
+	return head + 1, bloomBitsSectionSize, nil
+}
+
+// Progress is an alias for Sections with the name AdminIndexerAPI's
+// SectionProgress interface expects, so both BloombitsIndexer and
+// storage.ChainIndexer can be registered for progress reporting the same
+// way despite BitmapSource requiring the method be named Sections here.
+func (b *BloombitsIndexer) Progress() (sections uint64, size uint64, err error) {
+	return b.Sections()
+}
+
+// Bitmap retrieves and decompresses the bitmap for the given section and
+// bloom bit index.
+func (b *BloombitsIndexer) Bitmap(section uint64, bit uint16) ([]byte, error) {
+	val, err := b.store.get(bloomBitsKey, bloomBitsStorageKey(section, bit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bloom-bits for section %d bit %d: %w", section, bit, err)
+	}
+	return decompressBitmap(val, bloomBitsSectionSize/8)
+}
+
+// Rollback discards any sections at or above the given EVM height, used when
+// CrossSporkClient reorg handling rewinds the chain past a section boundary
+// that was already indexed. The section containing height is not dropped
+// outright: its blocks before height are still valid post-reorg, so it is
+// reloaded (or kept, if still in-progress) and only truncated from height
+// onward, then left pending so indexing can resume filling it back in.
+func (b *BloombitsIndexer) Rollback(height uint64) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	section := height / bloomBitsSectionSize
+	offset := uint(height % bloomBitsSectionSize)
+
+	for s := range b.pending {
+		if s > section {
+			delete(b.pending, s)
+		}
+	}
+
+	sec, ok := b.pending[section]
+	if !ok {
+		var err error
+		sec, err = b.loadSection(section)
+		if err != nil && !errors.Is(err, errs.NotFound) {
+			return fmt.Errorf("failed to reload bloom-bits section %d for rollback: %w", section, err)
+		}
+		if sec == nil {
+			sec = newBloomBitsSection(section)
+		}
+	}
+	sec.truncate(offset)
+	b.pending[section] = sec
+
+	b.next.Store(height)
+
+	if section == 0 {
+		return b.store.db.Delete(makePrefix(bloomBitsHeadKey), pebble.Sync)
+	}
+
+	return b.store.set(bloomBitsHeadKey, nil, uint64Bytes(section-1), nil)
+}
+
+// loadSection reconstructs a bloomBitsSection from a previously committed
+// section's stored bitmaps, used by Rollback to resurrect a section whose
+// tail must be discarded instead of losing the whole section outright.
+func (b *BloombitsIndexer) loadSection(number uint64) (*bloomBitsSection, error) {
+	sec := newBloomBitsSection(number)
+	for bit := 0; bit < bloomBitsLength; bit++ {
+		val, err := b.store.get(bloomBitsKey, bloomBitsStorageKey(number, uint16(bit)))
+		if err != nil {
+			if errors.Is(err, errs.NotFound) {
+				return nil, errs.NotFound
+			}
+			return nil, err
+		}
+		bm, err := decompressBitmap(val, bloomBitsSectionSize/8)
+		if err != nil {
+			return nil, err
+		}
+		sec.bits[bit] = bm
+	}
+	return sec, nil
+}
+
+func bloomBitsStorageKey(section uint64, bit uint16) []byte {
+	key := make([]byte, 10)
+	binary.BigEndian.PutUint64(key[:8], section)
+	binary.BigEndian.PutUint16(key[8:], bit)
+	return key
+}
+
+func compressBitmap(bitmap []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(bitmap); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressBitmap(compressed []byte, size int) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	out := make([]byte, size)
+	if _, err := io.ReadFull(r, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
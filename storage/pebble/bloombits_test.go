@@ -0,0 +1,51 @@
+package pebble
+
+import (
+	"testing"
+
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBloomBitsSection_SetMatchesBloomIndexConvention pins down the
+// byte/bit convention bloomBitsSection.set uses to the one services/logs's
+// bloomIndexes compiles filter queries against (go-ethereum's real
+// Bloom.Add/Test convention). If these ever disagree again, Matcher would
+// silently query the wrong physical bit plane.
+func TestBloomBitsSection_SetMatchesBloomIndexConvention(t *testing.T) {
+	var bloom gethTypes.Bloom
+	bloom.Add([]byte("some address"))
+
+	section := newBloomBitsSection(0)
+	section.set(0, &bloom)
+
+	for i := 0; i < bloomBitsLength; i++ {
+		byteIdx := gethTypes.BloomByteLength - 1 - i/8
+		bitIdx := uint(i % 8)
+		want := bloom[byteIdx]&(1<<bitIdx) != 0
+		got := section.bits[i][0]&(1<<7) != 0
+		require.Equal(t, want, got, "bit %d", i)
+	}
+}
+
+// TestBloomBitsSection_TruncateClearsTailKeepsHead exercises the truncate
+// helper Rollback uses to discard a reorged tail of a section while
+// preserving the blocks before the reorg point.
+func TestBloomBitsSection_TruncateClearsTailKeepsHead(t *testing.T) {
+	var bloom gethTypes.Bloom
+	bloom.Add([]byte("addr"))
+
+	section := newBloomBitsSection(0)
+	section.set(2, &bloom)
+	section.set(10, &bloom)
+
+	section.truncate(5)
+
+	for i := 0; i < bloomBitsLength; i++ {
+		require.Zero(t, section.bits[i][10/8]&(1<<(7-10%8)), "bit %d at offset 10 should be cleared", i)
+	}
+
+	want := newBloomBitsSection(0)
+	want.set(2, &bloom)
+	require.Equal(t, want.bits, section.bits)
+}
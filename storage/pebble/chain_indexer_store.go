@@ -0,0 +1,70 @@
+package pebble
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/onflow/go-ethereum/common"
+
+	"github.com/onflow/flow-evm-gateway/storage"
+	errs "github.com/onflow/flow-evm-gateway/storage/errors"
+)
+
+// chainIndexerSectionKey stores, per indexer ID and section number, the
+// hash of the last block in that section: `chainIndexerSectionKey ||
+// indexerID || '|' || section (8 bytes) => hash`.
+const chainIndexerSectionKey byte = 23
+
+var _ storage.SectionCheckpointStore = &Storage{}
+
+// GetSectionHead implements storage.SectionCheckpointStore.
+func (s *Storage) GetSectionHead(indexerID string, section uint64) (common.Hash, bool, error) {
+	val, err := s.get(chainIndexerSectionKey, chainIndexerSectionStorageKey(indexerID, section))
+	if err != nil {
+		if errors.Is(err, errs.NotFound) {
+			return common.Hash{}, false, nil
+		}
+		return common.Hash{}, false, fmt.Errorf(
+			"failed to get section head for indexer %s section %d: %w",
+			indexerID,
+			section,
+			err,
+		)
+	}
+	return common.BytesToHash(val), true, nil
+}
+
+// SetSectionHead implements storage.SectionCheckpointStore.
+func (s *Storage) SetSectionHead(indexerID string, section uint64, head common.Hash) error {
+	if err := s.set(
+		chainIndexerSectionKey,
+		chainIndexerSectionStorageKey(indexerID, section),
+		head.Bytes(),
+		nil,
+	); err != nil {
+		return fmt.Errorf(
+			"failed to set section head for indexer %s section %d: %w",
+			indexerID,
+			section,
+			err,
+		)
+	}
+	return nil
+}
+
+// DeleteSectionHead implements storage.SectionCheckpointStore.
+func (s *Storage) DeleteSectionHead(indexerID string, section uint64) error {
+	return s.db.Delete(
+		makePrefix(chainIndexerSectionKey, chainIndexerSectionStorageKey(indexerID, section)),
+		pebble.Sync,
+	)
+}
+
+func chainIndexerSectionStorageKey(indexerID string, section uint64) []byte {
+	key := make([]byte, len(indexerID)+1+8)
+	copy(key, indexerID)
+	key[len(indexerID)] = '|'
+	copy(key[len(indexerID)+1:], uint64Bytes(section))
+	return key
+}
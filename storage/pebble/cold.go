@@ -0,0 +1,222 @@
+package pebble
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	gethTypes "github.com/onflow/go-ethereum/core/types"
+	"github.com/onflow/go-ethereum/rlp"
+	"github.com/rs/zerolog"
+
+	"github.com/onflow/flow-evm-gateway/storage"
+	errs "github.com/onflow/flow-evm-gateway/storage/errors"
+)
+
+// frozenBoundaryKey stores the EVM height below which blocks/receipts have
+// been migrated out of pebble into the freezer. Heights >= the boundary are
+// still served from pebble; heights below it are only available through the
+// freezer.
+const frozenBoundaryKey byte = 22
+
+// Freezer tables, in the fixed order every Append call must use; Retrieve
+// names its table.
+const (
+	freezerTableBlocks   = "blocks"
+	freezerTableReceipts = "receipts"
+	freezerTableBlooms   = "blooms"
+)
+
+// Migrator periodically moves blocks, receipts and blooms older than a
+// configured finality window out of the hot pebble store and into a
+// storage.Freezer, keeping pebble's size bounded even after long uptime.
+// The tx-hash-to-height mapping (receiptTxIDToHeightKey) is deliberately
+// never migrated or deleted: Freezer only supports lookups by height, with
+// no reverse index, so GetByTransactionID would have no way left to
+// resolve a frozen receipt's height if that mapping were removed. It's one
+// small entry per transaction, so it's left in pebble permanently.
+type Migrator struct {
+	store    *Storage
+	blocks   *Blocks
+	receipts *Receipts
+	freezer  storage.Freezer
+	log      zerolog.Logger
+
+	// finalityWindow is how many of the most recent blocks are kept hot in
+	// pebble regardless of the freezer; only heights older than
+	// latest-finalityWindow are eligible for migration.
+	finalityWindow uint64
+
+	done chan struct{}
+}
+
+// NewMigrator creates a migrator that freezes blocks/receipts once they fall
+// more than finalityWindow blocks behind the chain tip.
+func NewMigrator(
+	store *Storage,
+	blocks *Blocks,
+	receipts *Receipts,
+	freezer storage.Freezer,
+	finalityWindow uint64,
+	log zerolog.Logger,
+) *Migrator {
+	return &Migrator{
+		store:          store,
+		blocks:         blocks,
+		receipts:       receipts,
+		freezer:        freezer,
+		finalityWindow: finalityWindow,
+		log:            log.With().Str("component", "cold-storage-migrator").Logger(),
+		done:           make(chan struct{}),
+	}
+}
+
+// Start launches the background migration loop. Call Stop to terminate it.
+func (m *Migrator) Start() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.done:
+				return
+			case <-ticker.C:
+			}
+
+			if err := m.migrate(); err != nil {
+				m.log.Error().Err(err).Msg("failed to migrate cold data")
+			}
+		}
+	}()
+}
+
+// Stop terminates the background migration loop.
+func (m *Migrator) Stop() {
+	close(m.done)
+}
+
+// migrate moves every eligible height from pebble into the freezer, one at
+// a time: the hot copy is only deleted once both the freezer data and index
+// files for that height have been fsynced.
+func (m *Migrator) migrate() error {
+	latest, err := m.blocks.LatestEVMHeight()
+	if err != nil {
+		return fmt.Errorf("failed to read latest height: %w", err)
+	}
+
+	if latest < m.finalityWindow {
+		return nil
+	}
+	target := latest - m.finalityWindow
+
+	boundary, err := frozenBoundary(m.store)
+	if err != nil {
+		return fmt.Errorf("failed to read frozen boundary: %w", err)
+	}
+
+	for height := boundary; height < target; height++ {
+		if err := m.migrateHeight(height); err != nil {
+			return fmt.Errorf("failed to migrate height %d: %w", height, err)
+		}
+	}
+
+	return nil
+}
+
+// migrateHeight freezes a single height and, in the same pebble batch,
+// deletes its hot copy and advances frozenBoundary past it. Folding the
+// boundary bump into the hot-copy-delete batch makes the pebble side of a
+// migration atomic: a crash can never leave frozenBoundary pointing past a
+// height whose hot copy is still present, or vice versa, so a restart always
+// resumes migrate()'s loop at a height that's consistently either fully hot
+// or fully frozen.
+func (m *Migrator) migrateHeight(height uint64) error {
+	block, err := m.blocks.getBlock(blockHeightKey, uint64Bytes(height))
+	if err != nil {
+		return err
+	}
+	blockBytes, err := block.ToBytes()
+	if err != nil {
+		return err
+	}
+
+	receipts, err := m.receipts.getByBlockHeight(uint64Bytes(height), nil)
+	if err != nil {
+		return err
+	}
+	receiptBytes, err := receiptsToBytes(receipts)
+	if err != nil {
+		return err
+	}
+
+	blooms := make([]*gethTypes.Bloom, len(receipts))
+	for i, receipt := range receipts {
+		blooms[i] = &receipt.Bloom
+	}
+
+	bloomBytes, err := rlp.EncodeToBytes(blooms)
+	if err != nil {
+		return fmt.Errorf("failed to encode blooms for height %d: %w", height, err)
+	}
+
+	// A crash after a previous Append of this height but before the batch
+	// below committed leaves it as the freezer's last item while its hot
+	// copy is still in pebble; it is not appended a second time.
+	tail, head, err := m.freezer.Frozen()
+	if err != nil {
+		return err
+	}
+	alreadyFrozen := head > tail && height == head-1
+
+	// Append fsyncs both the data and index files of every table before
+	// returning, so by the time we delete the hot copy below the cold copy
+	// is already durable.
+	if !alreadyFrozen {
+		if err := m.freezer.Append(height, [][]byte{blockBytes, receiptBytes, bloomBytes}); err != nil {
+			return err
+		}
+	}
+
+	batch := m.store.db.NewBatch()
+	defer batch.Close()
+
+	if err := batch.Delete(makePrefix(blockHeightKey, uint64Bytes(height)), nil); err != nil {
+		return err
+	}
+	if err := batch.Delete(makePrefix(receiptHeightKey, uint64Bytes(height)), nil); err != nil {
+		return err
+	}
+	if err := batch.Delete(makePrefix(bloomHeightKey, uint64Bytes(height)), nil); err != nil {
+		return err
+	}
+	// receiptTxIDToHeightKey entries are intentionally left alone; see the
+	// Migrator doc comment.
+	if err := m.store.set(frozenBoundaryKey, nil, uint64Bytes(height+1), batch); err != nil {
+		return err
+	}
+
+	return batch.Commit(pebble.Sync)
+}
+
+// frozenBoundary returns the EVM height below which data has been migrated
+// to the freezer. A height of 0 means nothing has been migrated yet.
+func frozenBoundary(store *Storage) (uint64, error) {
+	val, err := store.get(frozenBoundaryKey)
+	if err != nil {
+		if errors.Is(err, errs.NotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(val), nil
+}
+
+// ColdStats reports hot vs cold hit counts, for operators to gauge whether
+// the finality window is sized appropriately for their traffic.
+type ColdStats struct {
+	HotHits  uint64
+	ColdHits uint64
+}
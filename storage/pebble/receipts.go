@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/cockroachdb/pebble"
 	"github.com/onflow/go-ethereum/common"
@@ -18,8 +19,12 @@ import (
 var _ storage.ReceiptIndexer = &Receipts{}
 
 type Receipts struct {
-	store *Storage
-	mux   sync.RWMutex
+	store   *Storage
+	mux     sync.RWMutex
+	freezer storage.Freezer
+
+	hotHits  atomic.Uint64
+	coldHits atomic.Uint64
 }
 
 func NewReceipts(store *Storage) *Receipts {
@@ -29,6 +34,25 @@ func NewReceipts(store *Storage) *Receipts {
 	}
 }
 
+// SetFreezer wires up the cold storage tier consulted once a height falls
+// below the frozen boundary migrated by a Migrator. It is optional: a
+// Receipts index with no freezer just always reads from pebble.
+func (r *Receipts) SetFreezer(freezer storage.Freezer) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.freezer = freezer
+}
+
+// ColdStats reports how many GetByBlockHeight calls were served from pebble
+// versus the freezer, so operators can judge whether the configured
+// finality window matches their traffic's recency.
+func (r *Receipts) ColdStats() ColdStats {
+	return ColdStats{
+		HotHits:  r.hotHits.Load(),
+		ColdHits: r.coldHits.Load(),
+	}
+}
+
 // Store receipt in the index.
 //
 // Storing receipt will create multiple indexes, each receipt has a transaction ID, and a block height.
@@ -67,7 +91,7 @@ func (r *Receipts) Store(receipts []*models.StorageReceipt, batch *pebble.Batch)
 		}
 	}
 
-	receiptBytes, err := rlp.EncodeToBytes(receipts)
+	receiptBytes, err := receiptsToBytes(receipts)
 	if err != nil {
 		return err
 	}
@@ -90,16 +114,23 @@ func (r *Receipts) Store(receipts []*models.StorageReceipt, batch *pebble.Batch)
 	return nil
 }
 
+// GetByTransactionID looks up a receipt by its transaction hash. Unlike the
+// rest of a height's receipt data, receiptTxIDToHeightKey entries are never
+// migrated to the freezer or deleted by the Migrator (see the Migrator doc
+// comment in cold.go), so this keeps resolving tx hashes to heights however
+// old the receipt is. The receipts at that height are then read through
+// GetByBlockHeight, which falls back to the freezer once the height has been
+// migrated.
 func (r *Receipts) GetByTransactionID(ID common.Hash) (*models.StorageReceipt, error) {
 	r.mux.RLock()
-	defer r.mux.RUnlock()
-
-	height, err := r.store.get(receiptTxIDToHeightKey, ID.Bytes())
+	heightBytes, err := r.store.get(receiptTxIDToHeightKey, ID.Bytes())
+	r.mux.RUnlock()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get receipt by tx ID: %s, with: %w", ID, err)
 	}
+	height := binary.BigEndian.Uint64(heightBytes)
 
-	receipts, err := r.getByBlockHeight(height, nil)
+	receipts, err := r.GetByBlockHeight(height)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get receipt by height: %d, with: %w", height, err)
 	}
@@ -117,6 +148,22 @@ func (r *Receipts) GetByBlockHeight(height uint64) ([]*models.StorageReceipt, er
 	r.mux.RLock()
 	defer r.mux.RUnlock()
 
+	if r.freezer != nil {
+		boundary, err := frozenBoundary(r.store)
+		if err != nil {
+			return nil, err
+		}
+		if height < boundary {
+			val, err := r.freezer.Retrieve(freezerTableReceipts, height)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get frozen receipts at height: %d, with: %w", height, err)
+			}
+			r.coldHits.Add(1)
+			return decodeReceipts(val, height)
+		}
+	}
+
+	r.hotHits.Add(1)
 	return r.getByBlockHeight(uint64Bytes(height), nil)
 }
 
@@ -133,8 +180,15 @@ func (r *Receipts) getByBlockHeight(height []byte, batch *pebble.Batch) ([]*mode
 		return nil, err
 	}
 
+	return decodeReceipts(val, binary.BigEndian.Uint64(height))
+}
+
+// decodeReceipts RLP-decodes a block's receipts and populates the log
+// fields that aren't stored on disk to save space, whether the bytes came
+// from pebble or the freezer.
+func decodeReceipts(val []byte, height uint64) ([]*models.StorageReceipt, error) {
 	var receipts []*models.StorageReceipt
-	if err = rlp.DecodeBytes(val, &receipts); err != nil {
+	if err := rlp.DecodeBytes(val, &receipts); err != nil {
 		return nil, fmt.Errorf(
 			"failed to RLP-decode block receipts [%x] at height: %d, with: %w",
 			val,
@@ -157,6 +211,16 @@ func (r *Receipts) getByBlockHeight(height []byte, batch *pebble.Batch) ([]*mode
 	return receipts, nil
 }
 
+// BloomsForBlockRange returns every block's blooms in [start, end] by
+// reading them one height at a time - the same linear cost regardless of how
+// selective the caller's filter is. It exists as the tail fallback for
+// heights the bloom-bits index hasn't caught up to yet: log-filtering
+// callers such as eth_getLogs should go through logs.Matcher.MatchHeights,
+// which consults the index first and only falls back to this scan for that
+// unindexed tail, rather than calling this directly for the whole range.
+// Only blooms still held in pebble are returned: heights a Migrator has
+// moved to the freezer are missing from the result, and the freezer is not
+// read here.
 func (r *Receipts) BloomsForBlockRange(start, end uint64) ([]*models.BloomsHeight, error) {
 	r.mux.RLock()
 	defer r.mux.RUnlock()
@@ -239,6 +303,13 @@ func (r *Receipts) BloomsForBlockRange(start, end uint64) ([]*models.BloomsHeigh
 	return bloomsHeights, nil
 }
 
+// receiptsToBytes RLP-encodes a block's receipts the same way whether
+// they're being written to pebble or appended to the freezer, so a frozen
+// receipt can be read back with the same decode path as a hot one.
+func receiptsToBytes(receipts []*models.StorageReceipt) ([]byte, error) {
+	return rlp.EncodeToBytes(receipts)
+}
+
 func (r *Receipts) getLast() (uint64, error) {
 	l, err := r.store.get(latestEVMHeightKey)
 	if err != nil {